@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// This file implements the bot-integration subsystem, which lets an external
+// bot process join a table and play over a message bus instead of a
+// WebSocket connection. It is intentionally bus-agnostic: "botBus" is a thin
+// interface that a NATS or Redis Streams implementation can satisfy, so that
+// swapping the transport does not touch any of the game logic below.
+
+// botRequestMoveTimeout is how long the server will wait for a bot to answer
+// a "bot.request_move" message before falling back to the normal idle
+// handling for the table.
+const botRequestMoveTimeout = 10 * time.Second
+
+// botBus is the publish/subscribe transport that the bot-integration
+// subsystem is built on. A NATS or Redis Streams client can satisfy it; no
+// implementation of it is included in this file.
+type botBus interface {
+	Publish(subject string, payload []byte) error
+	Subscribe(subject string, callback func(payload []byte, replyTo string)) (unsubscribe func(), err error)
+}
+
+// BotRegistry tracks which bots are available to be invited to a table and
+// which seat (if any) a bot occupies at each table. Only one bot seat is
+// tracked per table; inviting a second bot to the same table replaces the
+// first.
+type BotRegistry struct {
+	mutex sync.Mutex
+	bus   botBus
+	bots  map[string]*BotInfo // Keyed by bot name
+	seats map[int]*botSeat    // Keyed by table ID
+}
+
+// BotInfo describes a bot that has registered itself with the server and is
+// available to be invited to a table
+type BotInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Subject string `json:"subject"` // The subject the bot listens on for move requests
+}
+
+type botSeat struct {
+	bot       *BotInfo
+	tableID   int
+	playerIdx int
+}
+
+func newBotRegistry(bus botBus) *BotRegistry {
+	return &BotRegistry{
+		bus:   bus,
+		bots:  make(map[string]*BotInfo),
+		seats: make(map[int]*botSeat),
+	}
+}
+
+var bots = newBotRegistry(nil) // With a nil bus, "RequestBotMove" returns without publishing anything
+
+// Register adds (or updates) a bot's entry in the registry, so that table
+// owners can subsequently invite it by name.
+func (r *BotRegistry) Register(info *BotInfo) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.bots[info.Name] = info
+}
+
+// Invite seats a registered bot at the given table and player index. The
+// `Game.End` path later consults this to know which seats were occupied by
+// bots when writing the "bot_participants" row.
+func (r *BotRegistry) Invite(t *Table, name string, playerIdx int) error {
+	r.mutex.Lock()
+	info, ok := r.bots[name]
+	r.mutex.Unlock()
+	if !ok {
+		return errors.New("no bot is registered under the name of \"" + name + "\"")
+	}
+
+	r.mutex.Lock()
+	r.seats[t.ID] = &botSeat{
+		bot:       info,
+		tableID:   t.ID,
+		playerIdx: playerIdx,
+	}
+	r.mutex.Unlock()
+
+	logger.Info(t.GetName() + "Invited bot \"" + name + "\" to seat " + strconv.Itoa(playerIdx) + ".")
+	return nil
+}
+
+// botGameState is the sanitized view of a game that is published to a bot
+// when it is their turn to act. Hands are omitted according to the normal
+// Hanabi rules (a player cannot see their own cards).
+type botGameState struct {
+	TableID      int                  `json:"tableID"`
+	Variant      string               `json:"variant"`
+	Turn         int                  `json:"turn"`
+	ActivePlayer int                  `json:"activePlayer"`
+	Clues        int                  `json:"clues"`
+	Strikes      int                  `json:"strikes"`
+	DeckSize     int                  `json:"deckSize"`
+	DiscardPile  []SimpleCard         `json:"discardPile"`
+	OtherHands   map[int][]SimpleCard `json:"otherHands"` // Keyed by player index; the bot's own seat is omitted
+	Actions      []interface{}        `json:"actions"`
+	ReplySubject string               `json:"replySubject"`
+}
+
+// botMoveRequest is published on "bot.request_move" whenever it becomes a
+// bot's turn to act.
+type botMoveRequest struct {
+	Type  string       `json:"type"`
+	State botGameState `json:"state"`
+}
+
+// botMoveResponse is what a bot publishes back on the reply subject; the
+// server validates it exactly as it would a WebSocket action before
+// injecting it into the game.
+type botMoveResponse struct {
+	Type   string `json:"type"`
+	Target int    `json:"target"`
+	Value  int    `json:"value"`
+}
+
+// RequestBotMove publishes the sanitized game state to the bot occupying
+// the active seat and waits (up to "botRequestMoveTimeout") for its answer,
+// which is then validated and injected into the game as a normal action.
+//
+// This blocks on a channel receive for up to "botRequestMoveTimeout", so it
+// must always be invoked in its own goroutine (see "OnTurnBegin") rather
+// than from the command-processing goroutine — otherwise every other table
+// on the server would stall for up to 10 seconds waiting for a bot to move.
+func (g *Game) RequestBotMove() {
+	t := g.Table
+
+	// Take the snapshot of the game state under the command mutex, just like
+	// the turn-timer and idle-timer callbacks do, since "g" can
+	// otherwise be mutated concurrently by any other command for this table
+	// (chat, a takeback, an admin termination, etc.) while we read it here
+	commandMutex.Lock()
+	r := bots
+	r.mutex.Lock()
+	seat, ok := r.seats[t.ID]
+	r.mutex.Unlock()
+	if !ok || seat.playerIdx != g.ActivePlayer || r.bus == nil {
+		commandMutex.Unlock()
+		return
+	}
+
+	replySubject := "bot.reply." + t.GetName() + "." + strconv.Itoa(g.Turn)
+	state := g.sanitizedStateFor(seat.playerIdx)
+	state.ReplySubject = replySubject
+	commandMutex.Unlock()
+
+	payload, err := json.Marshal(&botMoveRequest{Type: "bot.request_move", State: state})
+	if err != nil {
+		logger.Error("Failed to marshal the bot move request:", err)
+		return
+	}
+
+	done := make(chan *botMoveResponse, 1)
+	unsubscribe, err := r.bus.Subscribe(replySubject, func(payload []byte, _ string) {
+		var resp botMoveResponse
+		if err := json.Unmarshal(payload, &resp); err != nil {
+			logger.Error("Failed to unmarshal a bot move response:", err)
+			return
+		}
+		done <- &resp
+	})
+	if err != nil {
+		logger.Error("Failed to subscribe to the bot reply subject:", err)
+		return
+	}
+	defer unsubscribe()
+
+	if err := r.bus.Publish(seat.bot.Subject, payload); err != nil {
+		logger.Error("Failed to publish the bot move request:", err)
+		return
+	}
+
+	select {
+	case resp := <-done:
+		// Re-acquire the command mutex only for the brief moment it takes to
+		// inject the action, not for the entire wait above
+		commandMutex.Lock()
+		g.HandleAction(t.Players[seat.playerIdx].Session, ActionType(resp.Type), resp.Target, resp.Value)
+		commandMutex.Unlock()
+	case <-time.After(botRequestMoveTimeout):
+		logger.Info(t.GetName() + "Bot \"" + seat.bot.Name + "\" did not respond in time; " +
+			"falling back to the idle timeout.")
+	}
+}
+
+// sanitizedStateFor builds the view of the current game that is safe to
+// send to the player occupying "playerIdx" (i.e. with their own hand
+// hidden).
+func (g *Game) sanitizedStateFor(playerIdx int) botGameState {
+	t := g.Table
+
+	otherHands := make(map[int][]SimpleCard)
+	for i, gp := range g.Players {
+		if i == playerIdx {
+			continue
+		}
+		hand := make([]SimpleCard, 0, len(gp.Hand))
+		for _, c := range gp.Hand {
+			hand = append(hand, SimpleCard{Suit: c.Suit, Rank: c.Rank})
+		}
+		otherHands[i] = hand
+	}
+
+	discardPile := make([]SimpleCard, 0, len(g.DiscardPile))
+	for _, c := range g.DiscardPile {
+		discardPile = append(discardPile, SimpleCard{Suit: c.Suit, Rank: c.Rank})
+	}
+
+	return botGameState{
+		TableID:      t.ID,
+		Variant:      g.Options.Variant,
+		Turn:         g.Turn,
+		ActivePlayer: g.ActivePlayer,
+		Clues:        g.Clues,
+		Strikes:      g.Strikes,
+		DeckSize:     len(g.Deck) - g.DeckIndex,
+		DiscardPile:  discardPile,
+		OtherHands:   otherHands,
+		Actions:      g.Actions,
+	}
+}
@@ -218,10 +218,24 @@ func (g *Game) WriteDatabase() error {
 			gp.Index,
 			characterID,
 			gp.CharacterMetadata,
+			gp.ThinkTimes,
+			gp.TimewasteCount,
 		); err != nil {
 			logger.Error("Failed to insert game participant row #"+strconv.Itoa(i)+":", err)
 			return err
 		}
+
+		// If this seat was occupied by a bot, also record its identity and
+		// version alongside the participant row
+		bots.mutex.Lock()
+		seat, isBot := bots.seats[t.ID]
+		bots.mutex.Unlock()
+		if isBot && seat.playerIdx == gp.Index {
+			if err := models.BotParticipants.Insert(g.ID, p.ID, seat.bot.Name, seat.bot.Version); err != nil {
+				logger.Error("Failed to insert the bot participant row for seat #"+strconv.Itoa(i)+":", err)
+				// Do not return on failure, since it should not affect subsequent operations
+			}
+		}
 	}
 
 	// Next, we insert rows for each note
@@ -241,6 +255,15 @@ func (g *Game) WriteDatabase() error {
 		}
 	}
 
+	// Next, we insert rows for any actions that were undone by an accepted
+	// takeback, so that review tools can show what was rewound
+	for i, action := range g.TakenBackActions2 {
+		if err := models.GameActionsTakenBack.Insert(g.ID, i, action); err != nil {
+			logger.Error("Failed to insert row for taken-back action #"+strconv.Itoa(i)+":", err)
+			// Do not return on failure, since the final action stream below is authoritative
+		}
+	}
+
 	// Next, we insert rows for each of the actions
 	for i, action := range g.Actions2 {
 		// The index of the action in the slice is equivalent to the turn number that the
@@ -271,6 +294,12 @@ func (g *Game) WriteDatabase() error {
 			Target: 0,
 			Value:  EndConditionIdleTimeout,
 		}
+	} else if g.EndCondition == EndConditionAutoIdle {
+		gameOverAction = &GameAction{
+			Type:   ActionTypeGameOver,
+			Target: g.EndPlayer,
+			Value:  EndConditionAutoIdle,
+		}
 	}
 	if gameOverAction != nil {
 		if err := models.GameActions.Insert(g.ID, len(g.Actions2), gameOverAction); err != nil {
@@ -420,6 +449,16 @@ func (g *Game) GetAnnouncementString() string {
 func (t *Table) ConvertToSharedReplay() {
 	g := t.Game
 
+	// If this table belongs to a tournament, report the result so that the
+	// bracket can advance and the next table in the schedule can be created
+	if tourney := tournamentForTable(t.ID); tourney != nil {
+		scores := make(map[string]int)
+		for _, p := range t.Players {
+			scores[p.Name] = g.Score
+		}
+		tourney.OnGameEnded(t.ID, g.ID, scores)
+	}
+
 	t.Replay = true
 	t.Name = "Shared replay for game #" + strconv.Itoa(g.ID)
 	// Update the "EndTurn" field (since we incremented the final turn above in an artificial way)
@@ -446,7 +485,7 @@ func (t *Table) ConvertToSharedReplay() {
 
 		// If this game was ended due to idleness,
 		// skip conversion so that the shared replay gets deleted below
-		if g.EndCondition == EndConditionIdleTimeout {
+		if g.EndCondition == EndConditionIdleTimeout || g.EndCondition == EndConditionAutoIdle {
 			continue
 		}
 
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// replayExportSchemaVersion is bumped whenever the shape of "ReplayExport"
+// changes in a way that is not backwards-compatible, so that external tools
+// (and "getReplayJSON") can tell which fields to expect.
+const replayExportSchemaVersion = 1
+
+// ReplayExport is a canonical, self-contained representation of a finished
+// game. It is built from exactly the data already written by
+// "WriteDatabase" (the deck order, the action log, and per-player notes), so
+// that it round-trips: the same document can be fed back into a fresh
+// `Game` for offline analysis, or handed to a third-party solver.
+type ReplayExport struct {
+	SchemaVersion int                `json:"schemaVersion"`
+	ID            int                `json:"id"`
+	Variant       string             `json:"variant"`
+	Options       GameOptions        `json:"options"`
+	Seed          string             `json:"seed"`
+	Deck          []SimpleCard       `json:"deck"`
+	Actions       []interface{}      `json:"actions"` // Heterogeneous: plays/clues/discards, takeback markers, etc.
+	Notes         [][]string         `json:"notes"`   // Indexed by player, then by card order
+	Score         int                `json:"score"`
+	EndCondition  int                `json:"endCondition"`
+	Turns         []ReplayTurnTiming `json:"turns"`
+}
+
+// ReplayTurnTiming is how long each player took on each of their turns,
+// so that post-game analysis tools can show move-by-move timing
+type ReplayTurnTiming struct {
+	PlayerIndex int   `json:"playerIndex"`
+	ThinkTimeMS int64 `json:"thinkTimeMS"`
+}
+
+// BuildReplayExport assembles the canonical export document for a finished
+// game. It must be called after "WriteDatabase" has populated "g.ID", since
+// the deck order is only guaranteed to be complete once the game has ended.
+func (g *Game) BuildReplayExport() *ReplayExport {
+	deck := make([]SimpleCard, 0, len(g.Deck))
+	for _, c := range g.Deck {
+		deck = append(deck, SimpleCard{Suit: c.Suit, Rank: c.Rank})
+	}
+
+	notes := make([][]string, 0, len(g.Players))
+	turns := make([]ReplayTurnTiming, 0)
+	for _, gp := range g.Players {
+		notes = append(notes, gp.Notes)
+		for _, think := range gp.ThinkTimes {
+			turns = append(turns, ReplayTurnTiming{
+				PlayerIndex: gp.Index,
+				ThinkTimeMS: think.Milliseconds(),
+			})
+		}
+	}
+
+	return &ReplayExport{
+		SchemaVersion: replayExportSchemaVersion,
+		ID:            g.ID,
+		Variant:       g.Options.Variant,
+		Options:       g.Options,
+		Seed:          g.Seed,
+		Deck:          deck,
+		Actions:       g.Actions2,
+		Notes:         notes,
+		Score:         g.Score,
+		EndCondition:  g.EndCondition,
+		Turns:         turns,
+	}
+}
+
+// httpGameExport serves "GET /export/:id", returning the canonical JSON
+// replay document for a finished game
+func httpGameExport(c *gin.Context) {
+	idString := c.Param("id")
+	id, err := strconv.Atoi(idString)
+	if err != nil {
+		http.Error(c.Writer, "Error: The game ID must be an integer.", http.StatusBadRequest)
+		return
+	}
+
+	g, err := models.Games.GetGameForExport(id)
+	if err != nil {
+		logger.Error("Failed to get game "+idString+" for export:", err)
+		http.Error(c.Writer, "Error: That game does not exist.", http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, g.BuildReplayExport())
+}
+
+// getReplayJSON is the WebSocket equivalent of "httpGameExport", for clients
+// that would rather fetch the replay document over their existing
+// connection than open a separate HTTP request.
+func commandGetReplayJSON(s *Session, d *CommandData) {
+	g, err := models.Games.GetGameForExport(d.GameID)
+	if err != nil {
+		s.Error("That game does not exist.")
+		return
+	}
+
+	export := g.BuildReplayExport()
+	payload, err := json.Marshal(export)
+	if err != nil {
+		logger.Error("Failed to marshal the replay export for game "+strconv.Itoa(d.GameID)+":", err)
+		s.Error("Something went wrong when building the replay export.")
+		return
+	}
+
+	type ReplayJSONMessage struct {
+		GameID int             `json:"gameID"`
+		Export json.RawMessage `json:"export"`
+	}
+	s.Emit("replayJSON", &ReplayJSONMessage{
+		GameID: d.GameID,
+		Export: payload,
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+// timewasteLimit is how many consecutive soft-deadline misses a player is
+// allowed before the server steps in, either by auto-playing a safe discard
+// on their behalf or by terminating the game outright.
+const timewasteLimit = 3
+
+// idleSoftDeadline is how long a player has to act before a miss is counted
+// against "timewasteLimit". Unlike the hard "TimePerTurn" clock in
+// game_turn_timer.go, this applies even in untimed games, since idling
+// indefinitely should still eventually prompt the server to step in.
+const idleSoftDeadline = 2 * time.Minute
+
+// StartIdleTimer begins tracking the soft per-turn deadline for the active
+// player. It is scheduled fresh at the start of every turn, and cancelled
+// (see "StopIdleTimer") as soon as that player acts.
+func (g *Game) StartIdleTimer() {
+	turn := g.Turn
+	g.idleTimer = time.AfterFunc(idleSoftDeadline, func() {
+		commandMutex.Lock()
+		defer commandMutex.Unlock()
+		if g.Turn != turn || g.DatetimeFinished.Unix() > 0 {
+			// The turn has already advanced (or the game already ended) since
+			// this timer was scheduled, so there is nothing left to record
+			return
+		}
+		g.RecordTimewaste()
+	})
+}
+
+// StopIdleTimer cancels the pending soft-deadline check for the current
+// turn
+func (g *Game) StopIdleTimer() {
+	if g.idleTimer != nil {
+		g.idleTimer.Stop()
+		g.idleTimer = nil
+	}
+}
+
+// RecordTimewaste is called whenever a player fails to act before their soft
+// per-turn deadline. It increments their consecutive-miss counter and, once
+// "timewasteLimit" is reached, either auto-plays a safe discard for them or
+// ends the game, depending on whether a safe discard exists.
+func (g *Game) RecordTimewaste() {
+	t := g.Table
+
+	gp := g.Players[g.ActivePlayer]
+	gp.TimewasteCount++
+
+	if gp.TimewasteCount < timewasteLimit {
+		return
+	}
+
+	p := t.Players[gp.Index]
+	if order, ok := gp.LeftmostUnCluedCard(); ok {
+		logger.Info(t.GetName() + p.Name + " idled out " + strconv.Itoa(timewasteLimit) +
+			" turns in a row; auto-discarding their leftmost unclued card.")
+		t.Chat = append(t.Chat, &ChatMessage{
+			Msg: p.Name + " was idle for too many turns in a row and had a card auto-discarded.",
+		})
+		g.HandleAction(p.Session, ActionTypeDiscard, order, 0)
+		gp.TimewasteCount = 0
+		return
+	}
+
+	logger.Info(t.GetName() + p.Name + " idled out " + strconv.Itoa(timewasteLimit) +
+		" turns in a row with no safe discard available; ending the game.")
+	t.Chat = append(t.Chat, &ChatMessage{
+		Msg: p.Name + " was idle for too many turns in a row and the game was automatically ended.",
+	})
+	g.EndCondition = EndConditionAutoIdle
+	g.EndPlayer = g.ActivePlayer
+	g.End()
+}
+
+// ResetTimewaste clears a player's consecutive idle-miss counter. It is
+// called whenever they take any voluntary action, since the counter should
+// only measure a *streak* of misses, not a lifetime total.
+func (g *Game) ResetTimewaste() {
+	gp := g.Players[g.ActivePlayer]
+	gp.TimewasteCount = 0
+}
+
+// LeftmostUnCluedCard returns the order of the leftmost card in this
+// player's hand that has not received any clues, which is the card the
+// server auto-discards on their behalf when they idle out. The second
+// return value is false if every card in their hand has been clued, in
+// which case there is no safe discard to make automatically.
+func (gp *GamePlayer) LeftmostUnCluedCard() (int, bool) {
+	for _, c := range gp.Hand {
+		if !c.Clued {
+			return c.Order, true
+		}
+	}
+	return 0, false
+}
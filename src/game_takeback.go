@@ -0,0 +1,159 @@
+package main
+
+import "strconv"
+
+// takebackLimit is how many accepted takebacks a single player may have over
+// the course of one game, to prevent the feature from being used to grief the
+// pace of play. Rejected requests do not count against it.
+const takebackLimit = 3
+
+// HumanTakeback is proposed by a player who wants to undo the last action.
+// It mirrors lila's "TakebackSituation": the other players must unanimously
+// accept before the game is actually rewound.
+type HumanTakeback struct {
+	ProposerIndex int
+	Accepted      map[int]bool // Keyed by player index
+}
+
+// ActionTakebackRequest is broadcast when a player proposes a takeback
+type ActionTakebackRequest struct {
+	Type          string `json:"type"`
+	ProposerIndex int    `json:"proposerIndex"`
+}
+
+// ActionTakebackAccepted is appended to the action log (and thus rendered
+// explicitly in replays) once every other player has accepted a takeback
+type ActionTakebackAccepted struct {
+	Type          string `json:"type"`
+	ProposerIndex int    `json:"proposerIndex"`
+	RewoundToTurn int    `json:"rewoundToTurn"`
+}
+
+// ActionTakebackRejected is broadcast when any player declines a pending
+// takeback, so that the other clients can dismiss their prompt instead of
+// leaving it dangling.
+type ActionTakebackRejected struct {
+	Type          string `json:"type"`
+	ProposerIndex int    `json:"proposerIndex"`
+}
+
+// ProposeTakeback starts a takeback vote for the last action. It refuses in
+// Speedrun mode (where the whole point is minimizing elapsed time) and once
+// a player has exhausted their "takebackLimit".
+func (g *Game) ProposeTakeback(playerIndex int) {
+	t := g.Table
+
+	if g.Options.Speedrun {
+		logger.Info(t.GetName() + "A takeback was requested, but takebacks are disabled in Speedrun mode.")
+		return
+	}
+	if g.PendingTakeback != nil {
+		t.Players[g.Players[playerIndex].Index].Session.Error(
+			"There is already a takeback request pending; wait for it to be resolved first.")
+		return
+	}
+	gp := g.Players[playerIndex]
+	if gp.TakebackCount >= takebackLimit {
+		t.Players[gp.Index].Session.Error("You have already used up all of your takebacks for this game.")
+		return
+	}
+	if len(g.Actions2) == 0 {
+		return
+	}
+
+	g.PendingTakeback = &HumanTakeback{
+		ProposerIndex: playerIndex,
+		Accepted:      map[int]bool{playerIndex: true},
+	}
+
+	t.NotifyGameAction2(&ActionTakebackRequest{
+		Type:          "takebackRequest",
+		ProposerIndex: playerIndex,
+	})
+}
+
+// RespondTakeback records one player's vote on the pending takeback. Once
+// every other player has accepted, the game is rewound by replaying
+// "g.Actions2[:n-1]" from the seed.
+func (g *Game) RespondTakeback(playerIndex int, accept bool) {
+	t := g.Table
+
+	tb := g.PendingTakeback
+	if tb == nil {
+		return
+	}
+
+	if !accept {
+		proposerIndex := tb.ProposerIndex
+		g.PendingTakeback = nil
+		t.NotifyGameAction2(&ActionTakebackRejected{
+			Type:          "takebackRejected",
+			ProposerIndex: proposerIndex,
+		})
+		logger.Info(t.GetName() + "A takeback request was rejected.")
+		return
+	}
+
+	tb.Accepted[playerIndex] = true
+	for _, gp := range g.Players {
+		if !tb.Accepted[gp.Index] {
+			// Still waiting on at least one player to respond
+			return
+		}
+	}
+
+	g.applyTakeback(tb.ProposerIndex)
+}
+
+// applyTakeback performs the actual rewind: it drops the last action,
+// replays everything before it from the seed, and records a distinct action
+// type so that replays can render the takeback explicitly rather than
+// silently showing a shorter game.
+func (g *Game) applyTakeback(proposerIndex int) {
+	t := g.Table
+
+	g.Players[proposerIndex].TakebackCount++
+
+	rewoundActions := g.Actions2[:len(g.Actions2)-1]
+	replayed := NewGameFromSeed(g.Seed, g.Options)
+	for _, action := range rewoundActions {
+		replayed.ReplayAction(action)
+	}
+
+	// Preserve the history of what happened so that "WriteDatabase" can
+	// persist both the rewound and the final action streams
+	g.TakenBackActions2 = append(g.TakenBackActions2, g.Actions2[len(g.Actions2)-1])
+	g.Actions2 = rewoundActions
+
+	g.Deck = replayed.Deck
+	g.DeckIndex = replayed.DeckIndex
+	g.Clues = replayed.Clues
+	g.Strikes = replayed.Strikes
+	g.Players = replayed.Players
+	g.Turn = replayed.Turn
+	g.ActivePlayer = replayed.ActivePlayer
+	// The discard pile and played stacks are part of the visible game state
+	// too; leaving them as-is would still show the undone action's effects
+	// after the rest of the state was rewound out from under them
+	g.DiscardPile = replayed.DiscardPile
+	g.Stacks = replayed.Stacks
+
+	g.Actions2 = append(g.Actions2, &ActionTakebackAccepted{
+		Type:          "takebackAccepted",
+		ProposerIndex: proposerIndex,
+		RewoundToTurn: g.Turn,
+	})
+	g.PendingTakeback = nil
+
+	t.NotifyGameAction()
+	t.NotifyTurn()
+
+	// The rewound active player otherwise gets neither a clock nor an idle
+	// timer until the next natural turn transition, since the timer that was
+	// scheduled before the takeback was for a turn number that no longer
+	// exists
+	g.OnTurnBegin()
+
+	logger.Info(t.GetName() + "A takeback was accepted; the game was rewound to turn " +
+		strconv.Itoa(g.Turn) + ".")
+}
@@ -0,0 +1,34 @@
+package main
+
+// This file is the integration point between the per-turn mechanisms
+// introduced by several recent features and the action-dispatch code that
+// actually advances a turn. `OnActionTaken` runs immediately after an
+// action has been validated and appended to `g.Actions2`, while
+// `g.ActivePlayer` still refers to the player who just acted; `OnTurnBegin`
+// runs immediately afterward, once `g.ActivePlayer` has been advanced to
+// whoever goes next.
+//
+// Lag is tracked separately, via "commandClockAck" (see
+// game_turn_timer.go), since a round-trip sample has to come from how
+// quickly the client acknowledges the "clock" notification, not from how
+// long the player subsequently takes to submit their move.
+
+// OnActionTaken stops the clock and idle timer for the turn that just
+// ended and resets the acting player's timewaste streak, since taking any
+// voluntary action clears it.
+func (g *Game) OnActionTaken() {
+	g.StopTurnTimer()
+	g.StopIdleTimer()
+	g.ResetTimewaste()
+}
+
+// OnTurnBegin starts the new active player's clock and idle timer. If their
+// seat is occupied by a bot, it also kicks off the bot-integration flow in
+// its own goroutine, since "RequestBotMove" blocks on the bot's reply and
+// must not run on the command-processing goroutine.
+func (g *Game) OnTurnBegin() {
+	g.StartTurnTimer()
+	g.StartIdleTimer()
+
+	go g.RequestBotMove()
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// turnTimerGraceWindow is how long a player gets after their deadline elapses
+// before they are actually flagged; it absorbs the last leg of network jitter
+// so that a move which arrives a few hundred milliseconds late is not
+// penalized unfairly.
+const turnTimerGraceWindow = 500 * time.Millisecond
+
+// lagCache estimates each user's round-trip lag from their "clockAck"
+// timestamps, akin to lila's "UserLagCache". It is consulted
+// when computing a turn deadline so that laggy connections get a
+// commensurate amount of extra time instead of being flagged for network
+// delay rather than slow play.
+var lagCache = newUserLagCache()
+
+type userLagCache struct {
+	mutex sync.Mutex
+	lag   map[int]time.Duration
+}
+
+func newUserLagCache() *userLagCache {
+	return &userLagCache{
+		lag: make(map[int]time.Duration),
+	}
+}
+
+// Put records a single round-trip sample for a user as an exponentially
+// weighted moving average, so that one slow sample does not distort the
+// estimate as much as a sustained trend would.
+func (c *userLagCache) Put(userID int, sample time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	const weight = 0.2
+	if existing, ok := c.lag[userID]; ok {
+		c.lag[userID] = time.Duration(float64(existing)*(1-weight) + float64(sample)*weight)
+	} else {
+		c.lag[userID] = sample
+	}
+}
+
+func (c *userLagCache) Get(userID int) time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lag[userID]
+}
+
+// ActionClock is broadcast at the start of every turn in a timed game
+// It carries the absolute deadline (rather than a remaining duration) so
+// that late joiners and spectators can compute their own countdown locally
+// instead of having it drift by however long the message took to arrive.
+type ActionClock struct {
+	Type     string    `json:"type"`
+	Deadline time.Time `json:"deadline"`
+	Turn     int       `json:"turn"`
+}
+
+// StartTurnTimer begins tracking the deadline for the current player's turn
+// and schedules the server-side enforcement of it. It is a no-op for
+// untimed games, since those have no "TimePerTurn" deadline to enforce.
+func (g *Game) StartTurnTimer() {
+	if !g.Options.Timed || g.Options.TimePerTurn == 0 {
+		return
+	}
+	t := g.Table
+
+	gp := g.Players[g.ActivePlayer]
+	p := t.Players[gp.Index]
+	lag := lagCache.Get(p.ID)
+
+	g.TurnBeginTime = time.Now()
+	g.TurnDeadline = g.TurnBeginTime.
+		Add(time.Duration(g.Options.TimePerTurn) * time.Second).
+		Add(lag)
+
+	t.NotifyClock()
+
+	// The grace window is not part of the broadcast deadline; the player is
+	// only flagged once it has also elapsed
+	turn := g.Turn
+	g.turnTimer = time.AfterFunc(time.Until(g.TurnDeadline.Add(turnTimerGraceWindow)), func() {
+		commandMutex.Lock()
+		defer commandMutex.Unlock()
+		g.CheckTurnTimeout(turn)
+	})
+}
+
+// StopTurnTimer cancels the pending enforcement for the current turn and
+// records how long the active player actually took, so that "WriteDatabase"
+// can persist a per-turn think time for post-game analysis.
+func (g *Game) StopTurnTimer() {
+	if g.turnTimer != nil {
+		g.turnTimer.Stop()
+		g.turnTimer = nil
+	}
+	if g.TurnBeginTime.IsZero() {
+		return
+	}
+
+	thinkTime := time.Since(g.TurnBeginTime)
+	gp := g.Players[g.ActivePlayer]
+	gp.ThinkTimes = append(gp.ThinkTimes, thinkTime)
+	g.TurnBeginTime = time.Time{}
+}
+
+// RecordClientAck updates the lag estimate for a user from a "clockAck"
+// message, which the client sends the instant it receives the "clock"
+// notification for the turn that just began. That makes "ackTime -
+// g.TurnBeginTime" an actual network round-trip sample, as opposed to the
+// time the player subsequently spends deciding on a move — conflating the
+// two would let a player inflate their own future deadlines just by
+// thinking for a long time.
+func (g *Game) RecordClientAck(userID int, ackTime time.Time) {
+	if g.TurnBeginTime.IsZero() {
+		return
+	}
+	lagCache.Put(userID, ackTime.Sub(g.TurnBeginTime))
+}
+
+// commandClockAck handles the "clockAck" WebSocket message that a client
+// sends as soon as it receives the "clock" notification for a new turn
+func commandClockAck(s *Session, d *CommandData) {
+	t, ok := tables[d.TableID]
+	if !ok {
+		return
+	}
+	g := t.Game
+	if g == nil {
+		return
+	}
+	g.RecordClientAck(s.UserID(), time.Now())
+}
+
+// CheckTurnTimeout fires once a player's deadline and the grace window after
+// it have elapsed. It is guarded by
+// the turn number it was scheduled for, since the player may have already
+// taken their turn by the time the timer goes off.
+func (g *Game) CheckTurnTimeout(turn int) {
+	if g.Turn != turn || g.DatetimeFinished.Unix() > 0 {
+		// The turn has already advanced (or the game already ended) since this
+		// timer was scheduled, so there is nothing left to enforce
+		return
+	}
+	t := g.Table
+
+	gp := g.Players[g.ActivePlayer]
+	p := t.Players[gp.Index]
+	logger.Info(t.GetName() + p.Name + " ran out of time on turn " + strconv.Itoa(g.Turn) + ".")
+
+	g.EndCondition = EndConditionTimeout
+	g.EndPlayer = g.ActivePlayer
+	g.End()
+}
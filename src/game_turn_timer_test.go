@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestUserLagCacheFirstSampleIsExact verifies that the very first lag
+// sample for a user is stored as-is, with no averaging to dilute it.
+func TestUserLagCacheFirstSampleIsExact(t *testing.T) {
+	c := newUserLagCache()
+	c.Put(1, 100*time.Millisecond)
+
+	if got := c.Get(1); got != 100*time.Millisecond {
+		t.Errorf("expected 100ms, got %v", got)
+	}
+}
+
+// TestUserLagCacheWeightsRecentSamples verifies that the cache blends a new
+// sample with the existing estimate instead of either replacing it outright
+// or ignoring it, so that one outlier sample does not swing the estimate as
+// far as a sustained trend would.
+func TestUserLagCacheWeightsRecentSamples(t *testing.T) {
+	c := newUserLagCache()
+	c.Put(1, 100*time.Millisecond)
+	c.Put(1, 200*time.Millisecond)
+
+	got := c.Get(1)
+	if got <= 100*time.Millisecond || got >= 200*time.Millisecond {
+		t.Errorf("expected the estimate to land strictly between the two samples, got %v", got)
+	}
+}
+
+// TestUserLagCacheUnknownUser verifies that a user with no recorded samples
+// gets a zero estimate, so that "StartTurnTimer" adds no lag compensation
+// for players it has never heard an ack from yet.
+func TestUserLagCacheUnknownUser(t *testing.T) {
+	c := newUserLagCache()
+	if got := c.Get(999); got != 0 {
+		t.Errorf("expected 0 for an unknown user, got %v", got)
+	}
+}
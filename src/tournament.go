@@ -0,0 +1,293 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+)
+
+// This file implements a round-robin / Swiss tournament subsystem layered on
+// top of the normal table lifecycle. A tournament owns a schedule of games;
+// each time a game's `Table.ConvertToSharedReplay` fires, the controller
+// records the result, advances the bracket, and (if there are games left)
+// creates the next `Table` automatically.
+
+// TournamentPairingType selects how opponents are scheduled across rounds
+type TournamentPairingType int
+
+const (
+	TournamentPairingRoundRobin TournamentPairingType = iota
+	TournamentPairingSwiss
+)
+
+// Tournament groups a fixed set of players into a scheduled series of games
+// A "mirror-seed" strategy is used so that every pairing in a round plays
+// the same deal, which keeps luck from deciding the standings.
+type Tournament struct {
+	ID          int
+	Name        string
+	PairingType TournamentPairingType
+	Players     []string // Usernames, in signup order
+	Options     TableOptions
+	Rounds      [][]*TournamentMatch
+	Standings   map[string]int // Cumulative score, keyed by username
+	currentSeed int
+
+	mutex sync.Mutex
+}
+
+// TournamentMatch is a single scheduled game within a round
+type TournamentMatch struct {
+	Players   []string
+	Seed      string
+	TableID   int // 0 until the table has been created
+	GameID    int // 0 until the game has finished
+	Completed bool
+}
+
+var tournaments = make(map[int]*Tournament)
+var tournamentsMutex sync.Mutex
+var nextTournamentID = 1
+
+// NewRoundRobinTournament schedules every player against every other player
+// exactly once, using the same mirrored seed for every match in a round so
+// that no pairing is advantaged by an easier deal.
+func NewRoundRobinTournament(name string, players []string, options TableOptions) *Tournament {
+	t := &Tournament{
+		Name:        name,
+		PairingType: TournamentPairingRoundRobin,
+		Players:     players,
+		Options:     options,
+		Standings:   make(map[string]int),
+	}
+	for _, p := range players {
+		t.Standings[p] = 0
+	}
+	t.Rounds = roundRobinSchedule(players)
+	t.register()
+	t.AdvanceNextMatch()
+	return t
+}
+
+// NewSwissTournament schedules a single round by pairing players with the
+// closest current standings against each other; subsequent rounds are
+// generated on demand as each round completes, since Swiss pairings depend
+// on results that are not known up front.
+func NewSwissTournament(name string, players []string, options TableOptions) *Tournament {
+	t := &Tournament{
+		Name:        name,
+		PairingType: TournamentPairingSwiss,
+		Players:     players,
+		Options:     options,
+		Standings:   make(map[string]int),
+	}
+	for _, p := range players {
+		t.Standings[p] = 0
+	}
+	t.Rounds = [][]*TournamentMatch{t.swissRound()}
+	t.register()
+	t.AdvanceNextMatch()
+	return t
+}
+
+func (t *Tournament) register() {
+	tournamentsMutex.Lock()
+	defer tournamentsMutex.Unlock()
+	t.ID = nextTournamentID
+	nextTournamentID++
+	tournaments[t.ID] = t
+
+	// Persist the tournament so that a server restart can resume it instead
+	// of silently abandoning it mid-bracket
+	if err := models.Tournaments.Insert(t.ID, t.Name, int(t.PairingType), t.Players); err != nil {
+		logger.Error("Failed to insert the tournament row:", err)
+	}
+}
+
+// roundRobinSchedule pairs every player against every other player exactly
+// once using the standard circle method: fix one player and rotate the rest
+// around them each round.
+func roundRobinSchedule(players []string) [][]*TournamentMatch {
+	names := append([]string{}, players...)
+	if len(names)%2 != 0 {
+		names = append(names, "") // A "bye" if there is an odd number of players
+	}
+	n := len(names)
+	rounds := make([][]*TournamentMatch, 0, n-1)
+
+	for r := 0; r < n-1; r++ {
+		// Every match in a round shares one seed, so that each pairing plays
+		// the same deal
+		roundSeed := "tournament-r" + strconv.Itoa(r)
+		matches := make([]*TournamentMatch, 0, n/2)
+		for i := 0; i < n/2; i++ {
+			p1, p2 := names[i], names[n-1-i]
+			if p1 != "" && p2 != "" {
+				matches = append(matches, &TournamentMatch{
+					Players: []string{p1, p2},
+					Seed:    roundSeed,
+				})
+			}
+		}
+		rounds = append(rounds, matches)
+
+		// Rotate everyone except the first player
+		fixed := names[0]
+		rest := append([]string{names[n-1]}, names[1:n-1]...)
+		names = append([]string{fixed}, rest...)
+	}
+
+	return rounds
+}
+
+// swissRound pairs players with adjacent standings against each other
+func (t *Tournament) swissRound() []*TournamentMatch {
+	sorted := append([]string{}, t.Players...)
+	sortByStandings(sorted, t.Standings)
+
+	matches := make([]*TournamentMatch, 0, len(sorted)/2)
+	for i := 0; i+1 < len(sorted); i += 2 {
+		matches = append(matches, &TournamentMatch{
+			Players: []string{sorted[i], sorted[i+1]},
+			Seed:    "tournament-swiss-" + strconv.Itoa(len(t.Rounds)),
+		})
+	}
+	return matches
+}
+
+func sortByStandings(players []string, standings map[string]int) {
+	for i := 1; i < len(players); i++ {
+		for j := i; j > 0 && standings[players[j]] > standings[players[j-1]]; j-- {
+			players[j], players[j-1] = players[j-1], players[j]
+		}
+	}
+}
+
+// AdvanceNextMatch finds the next not-yet-started match in the current
+// round and creates a `Table` for it. It is called once at tournament
+// creation and again every time `ConvertToSharedReplay` completes a match.
+func (t *Tournament) AdvanceNextMatch() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for roundIdx, round := range t.Rounds {
+		for _, m := range round {
+			if m.TableID != 0 || m.Completed {
+				continue
+			}
+
+			newTable := NewTable(m.Players, m.Seed, t.Options)
+			m.TableID = newTable.ID
+			logger.Info("Tournament " + strconv.Itoa(t.ID) + ": created table " +
+				strconv.Itoa(newTable.ID) + " for round " + strconv.Itoa(roundIdx) + ".")
+			t.broadcastStandings()
+			return
+		}
+	}
+
+	// No match left to start; if this was a Swiss tournament, generate the
+	// next round now that every prior match has reported a result, and create
+	// the table for its first match
+	if t.PairingType == TournamentPairingSwiss && t.roundsComplete() {
+		round := t.swissRound()
+		t.Rounds = append(t.Rounds, round)
+		if len(round) > 0 {
+			m := round[0]
+			newTable := NewTable(m.Players, m.Seed, t.Options)
+			m.TableID = newTable.ID
+			logger.Info("Tournament " + strconv.Itoa(t.ID) + ": created table " +
+				strconv.Itoa(newTable.ID) + " for round " + strconv.Itoa(len(t.Rounds)-1) + ".")
+			t.broadcastStandings()
+		}
+	}
+}
+
+func (t *Tournament) roundsComplete() bool {
+	for _, round := range t.Rounds {
+		for _, m := range round {
+			if !m.Completed {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// OnGameEnded records the result of a finished match, aggregates the score
+// into the standings and into the `tournament_results` table, and advances
+// the bracket. It is invoked from `Table.ConvertToSharedReplay`.
+func (t *Tournament) OnGameEnded(tableID int, gameID int, scores map[string]int) {
+	t.mutex.Lock()
+	for _, round := range t.Rounds {
+		for _, m := range round {
+			if m.TableID == tableID {
+				m.GameID = gameID
+				m.Completed = true
+			}
+		}
+	}
+	for name, score := range scores {
+		t.Standings[name] += score
+	}
+	t.mutex.Unlock()
+
+	if err := models.TournamentResults.Insert(t.ID, gameID, scores); err != nil {
+		logger.Error("Failed to insert the tournament result row:", err)
+	}
+
+	t.AdvanceNextMatch()
+}
+
+// broadcastStandings sends an updated standings message to every
+// participant who is currently online
+func (t *Tournament) broadcastStandings() {
+	type StandingsMessage struct {
+		TournamentID int            `json:"tournamentID"`
+		Standings    map[string]int `json:"standings"`
+	}
+	msg := &StandingsMessage{
+		TournamentID: t.ID,
+		Standings:    t.Standings,
+	}
+	for _, name := range t.Players {
+		if s, ok := sessions[name]; ok {
+			s.Emit("tournamentStandings", msg)
+		}
+	}
+}
+
+// tournamentForTable finds the tournament (if any) that owns the given
+// table, so that `ConvertToSharedReplay` knows whether to notify a
+// controller after the game ends.
+func tournamentForTable(tableID int) *Tournament {
+	// "tournamentsMutex" only protects the "tournaments" map itself; each
+	// tournament's "Rounds" and match fields are protected by its own
+	// "t.mutex" (the same lock "OnGameEnded"/"AdvanceNextMatch" take), so
+	// take a snapshot of the map first and then lock each tournament in turn
+	tournamentsMutex.Lock()
+	candidates := make([]*Tournament, 0, len(tournaments))
+	for _, t := range tournaments {
+		candidates = append(candidates, t)
+	}
+	tournamentsMutex.Unlock()
+
+	for _, t := range candidates {
+		t.mutex.Lock()
+		found := false
+		for _, round := range t.Rounds {
+			for _, m := range round {
+				if m.TableID == tableID {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		t.mutex.Unlock()
+		if found {
+			return t
+		}
+	}
+	return nil
+}
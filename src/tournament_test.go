@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+// TestRoundRobinScheduleMirrorsSeedPerRound verifies that every match in a
+// round shares one seed (so that every pairing in that round plays the same
+// deal), rather than each match getting its own unique seed.
+func TestRoundRobinScheduleMirrorsSeedPerRound(t *testing.T) {
+	players := []string{"alice", "bob", "carol", "dave"}
+	rounds := roundRobinSchedule(players)
+
+	if len(rounds) != len(players)-1 {
+		t.Fatalf("expected %d rounds, got %d", len(players)-1, len(rounds))
+	}
+
+	for i, round := range rounds {
+		if len(round) == 0 {
+			t.Fatalf("round %d has no matches", i)
+		}
+		seed := round[0].Seed
+		for _, m := range round {
+			if m.Seed != seed {
+				t.Errorf("round %d: expected every match to share seed %q, got %q", i, seed, m.Seed)
+			}
+		}
+	}
+}
+
+// TestRoundRobinSchedulePairsEveryoneOnce verifies that every player faces
+// every other player exactly once across the full schedule.
+func TestRoundRobinSchedulePairsEveryoneOnce(t *testing.T) {
+	players := []string{"alice", "bob", "carol", "dave", "eve"}
+	rounds := roundRobinSchedule(players)
+
+	seen := make(map[string]int)
+	for _, round := range rounds {
+		for _, m := range round {
+			key := m.Players[0] + "-" + m.Players[1]
+			reverseKey := m.Players[1] + "-" + m.Players[0]
+			seen[key]++
+			if seen[reverseKey] > 0 {
+				t.Errorf("pairing %v appeared more than once", m.Players)
+			}
+		}
+	}
+
+	expectedPairings := len(players) * (len(players) - 1) / 2
+	total := 0
+	for _, count := range seen {
+		total += count
+	}
+	if total != expectedPairings {
+		t.Errorf("expected %d total pairings, got %d", expectedPairings, total)
+	}
+}